@@ -28,8 +28,26 @@ import (
 // ErrKNF represents the error "key not found in cache"
 var ErrKNF = errors.New("key not found in cache")
 
+// CancelFunc releases a subscription obtained via Watch. Calling it has no
+// effect on the owner's in-flight fill or on any other subscriber for the
+// same key; it only stops delivery to, and closes, the caller's own channel
+type CancelFunc func()
+
+// WatchResult is delivered to a Watch subscriber once the owner's fill
+// completes: Data is populated on a successful fill, or Err on a failed one
+// (e.g. the owner's origin query errored, or the key was invalidated before
+// the fill finished), so a subscriber always learns why it got nothing back
+type WatchResult struct {
+	Data []byte
+	Err  error
+}
+
 // Cache is the interface for the supported caching fabrics
 // When making new cache types, Retrieve() must return an error on cache miss
+// Watch lets concurrent callers for the same cacheKey coalesce onto a single
+// origin fill: the first caller gets isOwner true and must fetch the value
+// itself, then deliver it via Store (or via Abandon, on failure); subsequent
+// callers for the same key get isOwner false and simply read ch
 type Cache interface {
 	Connect() error
 	Store(cacheKey string, data []byte, ttl time.Duration) error
@@ -39,6 +57,8 @@ type Cache interface {
 	BulkRemove(cacheKeys []string, noLock bool)
 	Close() error
 	Configuration() *config.CachingConfig
+	Watch(cacheKey string) (ch <-chan WatchResult, isOwner bool, cancel CancelFunc, err error)
+	Abandon(cacheKey string, err error)
 }
 
 // MemoryCache is the interface for an in-memory cache
@@ -52,6 +72,8 @@ type MemoryCache interface {
 	BulkRemove(cacheKeys []string, noLock bool)
 	Close() error
 	Configuration() *config.CachingConfig
+	Watch(cacheKey string) (ch <-chan WatchResult, isOwner bool, cancel CancelFunc, err error)
+	Abandon(cacheKey string, err error)
 	StoreReference(cacheKey string, data ReferenceObject, ttl time.Duration) error
 	RetrieveReference(cacheKey string, allowExpired bool) (interface{}, status.LookupStatus, error)
 }
@@ -68,6 +90,13 @@ func ObserveCacheMiss(cacheKey, cacheName, cacheType string) ([]byte, error) {
 	return nil, fmt.Errorf("value for key [%s] not in cache", cacheKey)
 }
 
+// ObserveCacheCoalesced records that a request was served by joining an
+// in-flight fill for the same key via Watch, rather than issuing its own
+// origin query, so thundering-herd protection is visible alongside hit/miss
+func ObserveCacheCoalesced(cacheName, cacheType string) {
+	ObserveCacheOperation(cacheName, cacheType, "get", "coalesced", 0)
+}
+
 // ObserveCacheDel records a cache deletion event
 func ObserveCacheDel(cache, cacheType string, count float64) {
 	ObserveCacheOperation(cache, cacheType, "del", "none", count)
@@ -0,0 +1,65 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package cache
+
+import "time"
+
+// Fetch returns the cached value for cacheKey, falling back to fetchFn on a
+// miss. Concurrent misses for the same cacheKey coalesce onto a single call
+// to fetchFn via Watch: the first caller performs the fetch and populates the
+// cache for everyone else, instead of every caller issuing its own origin
+// query. Origin clients (e.g. the Prometheus and InfluxDB proxies) should
+// call this from their request path rather than invoking fetchFn directly on
+// a cache miss, so thundering-herd protection is automatic across origins.
+func Fetch(c Cache, cacheName, cacheKey string, ttl time.Duration, fetchFn func() ([]byte, error)) ([]byte, error) {
+	cc := c.Configuration()
+	cacheType := cc.Type
+	if cacheType == "" {
+		cacheType = "memory"
+	}
+
+	if data, _, err := c.Retrieve(cacheKey, false); err == nil {
+		return data, nil
+	}
+
+	ch, isOwner, cancel, err := c.Watch(cacheKey)
+	if err != nil {
+		// Watch itself failed; fall back to an uncoalesced fetch rather than
+		// fail the request over a bookkeeping error
+		return fetchFn()
+	}
+
+	if !isOwner {
+		defer cancel()
+		ObserveCacheCoalesced(cacheName, cacheType)
+		res := <-ch
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Data, nil
+	}
+
+	data, err := fetchFn()
+	if err != nil {
+		c.Abandon(cacheKey, err)
+		return nil, err
+	}
+
+	// Store broadcasts the result to every subscriber queued behind this fill
+	if err := c.Store(cacheKey, data, ttl); err != nil {
+		c.Abandon(cacheKey, err)
+		return nil, err
+	}
+	return data, nil
+}
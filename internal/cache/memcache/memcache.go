@@ -0,0 +1,176 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package memcache provides a Memcached-backed implementation of the Cache
+// interface, allowing multiple Trickster replicas to share a cache tier
+package memcache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// maxMemcacheExpiration is the largest TTL (in seconds) that Memcached will treat as a
+// relative duration rather than a unix timestamp; Trickster never asks for longer than this
+const maxMemcacheExpiration = int32(60 * 60 * 24 * 30)
+
+// memcacheClient is the subset of *memcache.Client this package calls,
+// pulled out as an interface so tests can substitute a fake client instead
+// of dialing a real Memcached server
+type memcacheClient interface {
+	Get(key string) (*memcache.Item, error)
+	Set(item *memcache.Item) error
+	Touch(key string, seconds int32) error
+	Delete(key string) error
+	Ping() error
+}
+
+// Cache implements the Cache interface for Memcached
+type Cache struct {
+	Name   string
+	Config *config.CachingConfig
+	cache.Watcher
+
+	client memcacheClient
+
+	// Memcached doesn't report the aggregate size of the keys this instance
+	// has written, so sizes tracks the byte length this instance last stored
+	// per key, letting it report a running object/byte count of its own
+	// writes for ObserveCacheSizeChange, same as the memory cache does
+	mtx   sync.Mutex
+	sizes map[string]int
+	bytes int64
+}
+
+// Configuration returns the Configuration for the Cache object
+func (c *Cache) Configuration() *config.CachingConfig {
+	return c.Config
+}
+
+// Connect instantiates the Memcached client against the configured server list
+func (c *Cache) Connect() error {
+	log.Info("connecting to memcached", log.Pairs{"cacheName": c.Name, "servers": c.Config.Memcache.Servers})
+	client := memcache.New(c.Config.Memcache.Servers...)
+	client.Timeout = time.Duration(c.Config.Memcache.Timeout) * time.Millisecond
+	client.MaxIdleConns = c.Config.Memcache.MaxIdleConns
+	c.client = client
+	return c.client.Ping()
+}
+
+// Store places an object in the cache using the provided key and ttl
+func (c *Cache) Store(cacheKey string, data []byte, ttl time.Duration) error {
+	err := c.client.Set(&memcache.Item{Key: cacheKey, Value: data, Expiration: expirationSeconds(ttl)})
+	if err != nil {
+		_, err = cache.CacheError(cacheKey, c.Name, "memcache", "unable to write to memcache: %s - "+err.Error())
+		return err
+	}
+	cache.ObserveCacheOperation(c.Name, "memcache", "set", "none", float64(len(data)))
+	objectCount, byteCount := c.trackSize(cacheKey, len(data))
+	cache.ObserveCacheSizeChange(c.Name, "memcache", byteCount, objectCount)
+	c.Broadcast(cacheKey, data)
+	return nil
+}
+
+// trackSize records the byte length last stored for cacheKey and returns the
+// running object/byte counts across every key this instance has stored
+func (c *Cache) trackSize(cacheKey string, size int) (objectCount, byteCount int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.sizes == nil {
+		c.sizes = make(map[string]int)
+	}
+	if old, ok := c.sizes[cacheKey]; ok {
+		c.bytes -= int64(old)
+	}
+	c.sizes[cacheKey] = size
+	c.bytes += int64(size)
+	return int64(len(c.sizes)), c.bytes
+}
+
+// untrackSize forgets cacheKey and returns the running object/byte counts
+func (c *Cache) untrackSize(cacheKey string) (objectCount, byteCount int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if size, ok := c.sizes[cacheKey]; ok {
+		c.bytes -= int64(size)
+		delete(c.sizes, cacheKey)
+	}
+	return int64(len(c.sizes)), c.bytes
+}
+
+// Retrieve looks up an object in the cache using the provided key and returns it
+func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	item, err := c.client.Get(cacheKey)
+	if err != nil {
+		if err == memcache.ErrCacheMiss {
+			b, err2 := cache.ObserveCacheMiss(cacheKey, c.Name, "memcache")
+			return b, status.LookupStatusKeyMiss, err2
+		}
+		cache.ObserveCacheEvent(c.Name, "memcache", "error", err.Error())
+		return nil, status.LookupStatusError, err
+	}
+	cache.ObserveCacheOperation(c.Name, "memcache", "get", "hit", float64(len(item.Value)))
+	return item.Value, status.LookupStatusHit, nil
+}
+
+// SetTTL updates the TTL for the provided cacheKey
+func (c *Cache) SetTTL(cacheKey string, ttl time.Duration) {
+	if err := c.client.Touch(cacheKey, expirationSeconds(ttl)); err != nil {
+		cache.ObserveCacheEvent(c.Name, "memcache", "error", err.Error())
+	}
+}
+
+// Remove removes an object from the cache, abandoning any Watch subscribers
+// still waiting on it (e.g. one invalidated mid-fill or on TTL expiry)
+func (c *Cache) Remove(cacheKey string) {
+	if err := c.client.Delete(cacheKey); err != nil {
+		cache.ObserveCacheEvent(c.Name, "memcache", "error", err.Error())
+		c.Abandon(cacheKey, cache.ErrKNF)
+		return
+	}
+	cache.ObserveCacheDel(c.Name, "memcache", 1)
+	objectCount, byteCount := c.untrackSize(cacheKey)
+	cache.ObserveCacheSizeChange(c.Name, "memcache", byteCount, objectCount)
+	c.Abandon(cacheKey, cache.ErrKNF)
+}
+
+// BulkRemove removes a list of objects from the cache; noLock has no effect on
+// Memcached, which has no process-local lock to bypass
+func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
+	for _, cacheKey := range cacheKeys {
+		c.Remove(cacheKey)
+	}
+}
+
+// Close releases nothing and always returns nil; idle connections held by
+// the Memcached client are not closed
+func (c *Cache) Close() error {
+	return nil
+}
+
+// expirationSeconds converts a ttl into the int32 seconds value Memcached expects,
+// capping it at maxMemcacheExpiration so it is always interpreted as relative
+func expirationSeconds(ttl time.Duration) int32 {
+	s := int32(ttl.Seconds())
+	if s > maxMemcacheExpiration {
+		return maxMemcacheExpiration
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package memcache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bradfitz/gomemcache/memcache"
+
+	"github.com/Comcast/trickster/internal/cache/status"
+)
+
+// fakeMemcacheClient is a memcacheClient that never talks to a real server,
+// letting Retrieve's miss-vs-error branching be exercised directly
+type fakeMemcacheClient struct {
+	items  map[string]*memcache.Item
+	getErr error
+}
+
+func (f *fakeMemcacheClient) Get(key string) (*memcache.Item, error) {
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	if item, ok := f.items[key]; ok {
+		return item, nil
+	}
+	return nil, memcache.ErrCacheMiss
+}
+
+func (f *fakeMemcacheClient) Set(item *memcache.Item) error {
+	if f.items == nil {
+		f.items = make(map[string]*memcache.Item)
+	}
+	f.items[item.Key] = item
+	return nil
+}
+
+func (f *fakeMemcacheClient) Touch(key string, seconds int32) error { return nil }
+func (f *fakeMemcacheClient) Delete(key string) error               { delete(f.items, key); return nil }
+func (f *fakeMemcacheClient) Ping() error                           { return nil }
+
+func TestRetrieveReportsKeyMissOnErrCacheMiss(t *testing.T) {
+	c := &Cache{Name: "test", client: &fakeMemcacheClient{}}
+
+	_, ls, err := c.Retrieve("missing", false)
+	if err == nil {
+		t.Fatal("expected an error for a missing key")
+	}
+	if ls != status.LookupStatusKeyMiss {
+		t.Errorf("expected LookupStatusKeyMiss, got %v", ls)
+	}
+}
+
+func TestRetrieveReportsErrorOnOtherClientErrors(t *testing.T) {
+	connErr := errors.New("connection refused")
+	c := &Cache{Name: "test", client: &fakeMemcacheClient{getErr: connErr}}
+
+	_, ls, err := c.Retrieve("key1", false)
+	if err != connErr {
+		t.Fatalf("expected the underlying client error to be surfaced, got %v", err)
+	}
+	if ls == status.LookupStatusKeyMiss {
+		t.Error("expected a real client error not to be reported as a key miss")
+	}
+}
+
+func TestRetrieveReturnsDataOnHit(t *testing.T) {
+	client := &fakeMemcacheClient{items: map[string]*memcache.Item{
+		"key1": {Key: "key1", Value: []byte("value")},
+	}}
+	c := &Cache{Name: "test", client: client}
+
+	data, ls, err := c.Retrieve("key1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ls != status.LookupStatusHit {
+		t.Errorf("expected LookupStatusHit, got %v", ls)
+	}
+	if string(data) != "value" {
+		t.Errorf("expected value, got %q", data)
+	}
+}
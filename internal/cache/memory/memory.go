@@ -0,0 +1,267 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package memory defines the in-process Cache implementation, which keeps
+// all objects in a map guarded by a mutex
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+)
+
+// defaultReapIntervalSecs is used when the cache's configured reap interval is unset
+const defaultReapIntervalSecs = 60
+
+// cacheElement is the internal storage representation of a cached object
+type cacheElement struct {
+	data      []byte
+	reference cache.ReferenceObject
+	expiresAt time.Time
+}
+
+func (e *cacheElement) isExpired() bool {
+	return !e.expiresAt.IsZero() && time.Now().After(e.expiresAt)
+}
+
+// size reports the byte footprint of the element for the size-change gauges:
+// the raw data length, or the reference object's own reported size
+func (e *cacheElement) size() int {
+	if e.reference != nil {
+		return e.reference.Size()
+	}
+	return len(e.data)
+}
+
+// EvictionHandler is invoked, synchronously, whenever the TTL reaper evicts an
+// object from the store, giving a composing cache (such as a TieredCache) the
+// opportunity to spill it down to a slower tier before it is gone for good
+type EvictionHandler = func(cacheKey string, data []byte, ref cache.ReferenceObject)
+
+// Cache implements the Cache and MemoryCache interfaces for an in-process cache
+type Cache struct {
+	Name   string
+	Config *config.CachingConfig
+	cache.Watcher
+
+	mtx      sync.RWMutex
+	store    map[string]*cacheElement
+	bytes    int64
+	onEvict  EvictionHandler
+	stopReap chan struct{}
+}
+
+// SetEvictionHandler registers a callback to invoke when the TTL reaper
+// evicts an object from the store
+func (c *Cache) SetEvictionHandler(fn EvictionHandler) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.onEvict = fn
+}
+
+// Configuration returns the Configuration for the Cache object
+func (c *Cache) Configuration() *config.CachingConfig {
+	return c.Config
+}
+
+// Connect initializes the in-memory store and starts the TTL reaper
+func (c *Cache) Connect() error {
+	c.mtx.Lock()
+	if c.store == nil {
+		c.store = make(map[string]*cacheElement)
+	}
+	c.stopReap = make(chan struct{})
+	c.mtx.Unlock()
+
+	go c.reapLoop()
+	return nil
+}
+
+// reapLoop periodically scans the store for expired objects and removes them,
+// invoking the eviction handler (if any) for each one
+func (c *Cache) reapLoop() {
+	interval := time.Duration(c.Config.ReapIntervalSecs) * time.Second
+	if interval <= 0 {
+		interval = defaultReapIntervalSecs * time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			c.reap()
+		case <-c.stopReap:
+			return
+		}
+	}
+}
+
+func (c *Cache) reap() {
+	c.mtx.Lock()
+	expired := make([]*cacheElement, 0)
+	expiredKeys := make([]string, 0)
+	for k, e := range c.store {
+		if e.isExpired() {
+			delete(c.store, k)
+			c.bytes -= int64(e.size())
+			expired = append(expired, e)
+			expiredKeys = append(expiredKeys, k)
+		}
+	}
+	count := len(c.store)
+	byteCount := c.bytes
+	onEvict := c.onEvict
+	c.mtx.Unlock()
+
+	if len(expiredKeys) == 0 {
+		return
+	}
+
+	// onEvict may perform I/O (e.g. a TieredCache spilling to a remote L2),
+	// so it runs after the store lock is released to avoid blocking every
+	// other concurrent Store/Retrieve/Remove for the duration of the reap
+	if onEvict != nil {
+		for i, k := range expiredKeys {
+			onEvict(k, expired[i].data, expired[i].reference)
+		}
+	}
+
+	cache.ObserveCacheDel(c.Name, "memory", float64(len(expiredKeys)))
+	cache.ObserveCacheSizeChange(c.Name, "memory", byteCount, int64(count))
+	for _, k := range expiredKeys {
+		c.Abandon(k, cache.ErrKNF)
+	}
+}
+
+// Store places an object in the cache using the provided key and ttl
+func (c *Cache) Store(cacheKey string, data []byte, ttl time.Duration) error {
+	c.storeElement(cacheKey, &cacheElement{data: data, expiresAt: expiresAt(ttl)})
+	cache.ObserveCacheOperation(c.Name, "memory", "set", "none", float64(len(data)))
+	return nil
+}
+
+// StoreReference places a ReferenceObject in the cache, bypassing serialization
+func (c *Cache) StoreReference(cacheKey string, data cache.ReferenceObject, ttl time.Duration) error {
+	c.storeElement(cacheKey, &cacheElement{reference: data, expiresAt: expiresAt(ttl)})
+	cache.ObserveCacheOperation(c.Name, "memory", "set", "none", float64(data.Size()))
+	return nil
+}
+
+func (c *Cache) storeElement(cacheKey string, e *cacheElement) {
+	c.mtx.Lock()
+	if old, ok := c.store[cacheKey]; ok {
+		c.bytes -= int64(old.size())
+	}
+	c.store[cacheKey] = e
+	c.bytes += int64(e.size())
+	count := len(c.store)
+	byteCount := c.bytes
+	c.mtx.Unlock()
+	cache.ObserveCacheSizeChange(c.Name, "memory", byteCount, int64(count))
+	c.Broadcast(cacheKey, e.data)
+}
+
+// Retrieve looks up an object in the cache using the provided key and returns it
+// along with its lookup status. An object stored via StoreReference is not
+// serialized here: it is reported as a hit with nil data
+func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	c.mtx.RLock()
+	e, ok := c.store[cacheKey]
+	c.mtx.RUnlock()
+	if !ok || (!allowExpired && e.isExpired()) {
+		b, err := cache.ObserveCacheMiss(cacheKey, c.Name, "memory")
+		return b, status.LookupStatusKeyMiss, err
+	}
+	cache.ObserveCacheOperation(c.Name, "memory", "get", "hit", float64(len(e.data)))
+	return e.data, status.LookupStatusHit, nil
+}
+
+// RetrieveReference looks up a ReferenceObject in the cache using the provided key
+func (c *Cache) RetrieveReference(cacheKey string, allowExpired bool) (interface{}, status.LookupStatus, error) {
+	c.mtx.RLock()
+	e, ok := c.store[cacheKey]
+	c.mtx.RUnlock()
+	if !ok || (!allowExpired && e.isExpired()) {
+		_, err := cache.ObserveCacheMiss(cacheKey, c.Name, "memory")
+		return nil, status.LookupStatusKeyMiss, err
+	}
+	cache.ObserveCacheOperation(c.Name, "memory", "get", "hit", 0)
+	return e.reference, status.LookupStatusHit, nil
+}
+
+// SetTTL updates the TTL for the provided cacheKey
+func (c *Cache) SetTTL(cacheKey string, ttl time.Duration) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if e, ok := c.store[cacheKey]; ok {
+		e.expiresAt = expiresAt(ttl)
+	}
+}
+
+// Remove removes an object from the cache, abandoning any Watch subscribers
+// still waiting on it (e.g. one invalidated mid-fill or on TTL expiry)
+func (c *Cache) Remove(cacheKey string) {
+	c.mtx.Lock()
+	if e, ok := c.store[cacheKey]; ok {
+		c.bytes -= int64(e.size())
+	}
+	delete(c.store, cacheKey)
+	count := len(c.store)
+	byteCount := c.bytes
+	c.mtx.Unlock()
+	cache.ObserveCacheDel(c.Name, "memory", 1)
+	cache.ObserveCacheSizeChange(c.Name, "memory", byteCount, int64(count))
+	c.Abandon(cacheKey, cache.ErrKNF)
+}
+
+// BulkRemove removes a list of objects from the cache
+func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
+	if !noLock {
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+	}
+	for _, cacheKey := range cacheKeys {
+		if e, ok := c.store[cacheKey]; ok {
+			c.bytes -= int64(e.size())
+		}
+		delete(c.store, cacheKey)
+	}
+	cache.ObserveCacheDel(c.Name, "memory", float64(len(cacheKeys)))
+	cache.ObserveCacheSizeChange(c.Name, "memory", c.bytes, int64(len(c.store)))
+	for _, cacheKey := range cacheKeys {
+		c.Abandon(cacheKey, cache.ErrKNF)
+	}
+}
+
+// Close stops the TTL reaper and releases all resources held by the cache
+func (c *Cache) Close() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.stopReap != nil {
+		close(c.stopReap)
+		c.stopReap = nil
+	}
+	c.store = nil
+	return nil
+}
+
+func expiresAt(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
@@ -0,0 +1,43 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package cache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Caches is the list of registered, running Cache instances, keyed by name
+var Caches = make(map[string]Cache)
+
+var cachesMtx sync.RWMutex
+
+// GetCache returns the registered Cache with the given name, or an error if
+// no such cache has been registered
+func GetCache(cacheName string) (Cache, error) {
+	cachesMtx.RLock()
+	defer cachesMtx.RUnlock()
+	if c, ok := Caches[cacheName]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("could not find cache named [%s]", cacheName)
+}
+
+// RegisterCache adds the provided, already-connected Cache to the registry
+// under the given name, making it available to origins via GetCache
+func RegisterCache(cacheName string, c Cache) {
+	cachesMtx.Lock()
+	defer cachesMtx.Unlock()
+	Caches[cacheName] = c
+}
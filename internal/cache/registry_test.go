@@ -0,0 +1,60 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+)
+
+// registryTestCache is a no-op Cache used only to exercise the registry
+type registryTestCache struct {
+	Watcher
+}
+
+func (c *registryTestCache) Connect() error { return nil }
+func (c *registryTestCache) Store(cacheKey string, data []byte, ttl time.Duration) error {
+	return nil
+}
+func (c *registryTestCache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	return nil, status.LookupStatusKeyMiss, errors.New("key not found in cache")
+}
+func (c *registryTestCache) SetTTL(cacheKey string, ttl time.Duration)  {}
+func (c *registryTestCache) Remove(cacheKey string)                     {}
+func (c *registryTestCache) BulkRemove(cacheKeys []string, noLock bool) {}
+func (c *registryTestCache) Close() error                               { return nil }
+func (c *registryTestCache) Configuration() *config.CachingConfig       { return &config.CachingConfig{} }
+
+func TestGetCacheReturnsErrorForUnregisteredName(t *testing.T) {
+	if _, err := GetCache("does-not-exist"); err == nil {
+		t.Error("expected an error looking up a cache name that was never registered")
+	}
+}
+
+func TestRegisterCacheMakesItAvailableViaGetCache(t *testing.T) {
+	want := &registryTestCache{}
+	RegisterCache("registry-test-cache", want)
+
+	got, err := GetCache("registry-test-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != Cache(want) {
+		t.Error("expected GetCache to return the exact Cache instance that was registered")
+	}
+}
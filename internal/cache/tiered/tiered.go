@@ -0,0 +1,184 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package tiered provides a Cache implementation that composes two other
+// Caches into a fast L1 / slower-but-shared L2 pair
+package tiered
+
+import (
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// maxSpillableBytes caps how large an L1 eviction can be before it is no
+// longer considered worth serializing down to L2 on the way out
+const maxSpillableBytes = 1 << 20 // 1MB
+
+// defaultPromotionTTL is applied when an object is spilled from an evicted L1
+// to L2, or promoted back from an L2 hit into L1; the original TTL isn't
+// available at either point, since Cache.Retrieve doesn't report remaining TTL
+const defaultPromotionTTL = 5 * time.Minute
+
+// evictionSource is implemented by an L1 whose evictions can be observed,
+// e.g. internal/cache/memory.Cache; L1s that don't implement it simply run
+// without the evict-to-L2 behavior
+type evictionSource interface {
+	SetEvictionHandler(func(cacheKey string, data []byte, ref cache.ReferenceObject))
+}
+
+// serializableReference is implemented by a ReferenceObject that can also
+// produce a byte-serialized form of itself. ReferenceObject alone only
+// reports its size, with no generic way to serialize an arbitrary reference;
+// only references that additionally implement this are eligible to spill to
+// L2 on L1 eviction
+type serializableReference interface {
+	cache.ReferenceObject
+	Bytes() ([]byte, error)
+}
+
+// Cache composes two Cache implementations into a single two-level cache: L1
+// is checked first and is expected to be the faster of the pair (typically
+// an in-process memory.Cache); L2 is the shared, slower tier (e.g. Redis or a
+// disk-backed cache) that survives an L1 eviction or a process restart
+type Cache struct {
+	Name   string
+	Config *config.CachingConfig
+	cache.Watcher
+
+	L1 cache.Cache
+	L2 cache.Cache
+}
+
+// Configuration returns the Configuration for the Cache object
+func (c *Cache) Configuration() *config.CachingConfig {
+	return c.Config
+}
+
+// Connect wires up the L1 eviction hook that spills evicted objects down to
+// L2; L1 and L2 are expected to already be connected, since they are
+// independently-registered caches by the time a TieredCache is built
+func (c *Cache) Connect() error {
+	if es, ok := c.L1.(evictionSource); ok {
+		es.SetEvictionHandler(c.onL1Evict)
+	}
+	return nil
+}
+
+// onL1Evict is called when L1 evicts an object (e.g. on TTL expiry).
+// Eviction worthiness is always decided by size: raw data spills if it's
+// under maxSpillableBytes, and a reference object spills under the same
+// threshold if it also knows how to serialize itself. A reference that's
+// too large, or that can't produce a byte form of itself, is logged and
+// dropped rather than silently discarded.
+func (c *Cache) onL1Evict(cacheKey string, data []byte, ref cache.ReferenceObject) {
+	if ref != nil {
+		if ref.Size() > maxSpillableBytes {
+			log.Debug("reference object too large, skipping L2 spill", log.Pairs{"cacheName": c.Name, "cacheKey": cacheKey, "size": ref.Size()})
+			return
+		}
+		sr, ok := ref.(serializableReference)
+		if !ok {
+			log.Debug("reference object cannot be serialized, skipping L2 spill", log.Pairs{"cacheName": c.Name, "cacheKey": cacheKey})
+			return
+		}
+		b, err := sr.Bytes()
+		if err != nil {
+			log.Error("unable to serialize reference object for L2 spill", log.Pairs{"cacheName": c.Name, "cacheKey": cacheKey, "detail": err.Error()})
+			return
+		}
+		data = b
+	} else if len(data) == 0 || len(data) > maxSpillableBytes {
+		return
+	}
+
+	if err := c.L2.Store(cacheKey, data, defaultPromotionTTL); err != nil {
+		log.Error("unable to spill evicted object to L2 cache", log.Pairs{"cacheName": c.Name, "cacheKey": cacheKey, "detail": err.Error()})
+	}
+}
+
+// Store writes through to L1, then writes to L2 either synchronously or, if
+// configured, on a best-effort background goroutine
+func (c *Cache) Store(cacheKey string, data []byte, ttl time.Duration) error {
+	if err := c.L1.Store(cacheKey, data, ttl); err != nil {
+		return err
+	}
+	c.Broadcast(cacheKey, data)
+
+	if c.Config.TieredWriteBack {
+		go c.storeL2(cacheKey, data, ttl)
+		return nil
+	}
+	return c.storeL2(cacheKey, data, ttl)
+}
+
+func (c *Cache) storeL2(cacheKey string, data []byte, ttl time.Duration) error {
+	if err := c.L2.Store(cacheKey, data, ttl); err != nil {
+		log.Error("unable to write through to L2 cache", log.Pairs{"cacheName": c.Name, "cacheKey": cacheKey, "detail": err.Error()})
+		return err
+	}
+	return nil
+}
+
+// Retrieve checks L1 first, falling back to and promoting from L2 on an L1 miss
+func (c *Cache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	if data, ls, err := c.L1.Retrieve(cacheKey, allowExpired); err == nil {
+		return data, ls, nil
+	}
+
+	data, ls, err := c.L2.Retrieve(cacheKey, allowExpired)
+	if err != nil {
+		b, err := cache.ObserveCacheMiss(cacheKey, c.Name, "tiered")
+		return b, status.LookupStatusKeyMiss, err
+	}
+
+	if err := c.L1.Store(cacheKey, data, defaultPromotionTTL); err != nil {
+		log.Error("unable to promote object from L2 to L1 cache", log.Pairs{"cacheName": c.Name, "cacheKey": cacheKey, "detail": err.Error()})
+	}
+	return data, ls, nil
+}
+
+// SetTTL updates the TTL for the provided cacheKey on both tiers
+func (c *Cache) SetTTL(cacheKey string, ttl time.Duration) {
+	c.L1.SetTTL(cacheKey, ttl)
+	c.L2.SetTTL(cacheKey, ttl)
+}
+
+// Remove removes an object from both tiers
+func (c *Cache) Remove(cacheKey string) {
+	c.L1.Remove(cacheKey)
+	c.L2.Remove(cacheKey)
+	c.Abandon(cacheKey, cache.ErrKNF)
+}
+
+// BulkRemove removes a list of objects from both tiers
+func (c *Cache) BulkRemove(cacheKeys []string, noLock bool) {
+	c.L1.BulkRemove(cacheKeys, noLock)
+	c.L2.BulkRemove(cacheKeys, noLock)
+	for _, cacheKey := range cacheKeys {
+		c.Abandon(cacheKey, cache.ErrKNF)
+	}
+}
+
+// Close closes both tiers
+func (c *Cache) Close() error {
+	err1 := c.L1.Close()
+	err2 := c.L2.Close()
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
@@ -0,0 +1,203 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package tiered
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/status"
+	"github.com/Comcast/trickster/internal/config"
+)
+
+// fakeCache is a minimal, in-memory cache.Cache used to exercise TieredCache
+// without depending on a real backend
+type fakeCache struct {
+	cache.Watcher
+
+	mu         sync.Mutex
+	store      map[string][]byte
+	storeCalls int
+	storeErr   error
+	onEvict    func(cacheKey string, data []byte, ref cache.ReferenceObject)
+}
+
+func (f *fakeCache) Connect() error                            { return nil }
+func (f *fakeCache) Configuration() *config.CachingConfig      { return &config.CachingConfig{} }
+func (f *fakeCache) SetTTL(cacheKey string, ttl time.Duration) {}
+func (f *fakeCache) Close() error                              { return nil }
+
+func (f *fakeCache) Store(cacheKey string, data []byte, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.storeCalls++
+	if f.storeErr != nil {
+		return f.storeErr
+	}
+	if f.store == nil {
+		f.store = make(map[string][]byte)
+	}
+	f.store[cacheKey] = data
+	return nil
+}
+
+func (f *fakeCache) Retrieve(cacheKey string, allowExpired bool) ([]byte, status.LookupStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.store[cacheKey]
+	if !ok {
+		return nil, status.LookupStatusKeyMiss, errors.New("key not found in cache")
+	}
+	return data, status.LookupStatusHit, nil
+}
+
+func (f *fakeCache) Remove(cacheKey string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.store, cacheKey)
+}
+
+func (f *fakeCache) BulkRemove(cacheKeys []string, noLock bool) {
+	for _, k := range cacheKeys {
+		f.Remove(k)
+	}
+}
+
+func (f *fakeCache) SetEvictionHandler(fn func(cacheKey string, data []byte, ref cache.ReferenceObject)) {
+	f.onEvict = fn
+}
+
+func (f *fakeCache) has(cacheKey string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.store[cacheKey]
+	return ok
+}
+
+func TestRetrievePromotesFromL2OnL1Miss(t *testing.T) {
+	l1 := &fakeCache{}
+	l2 := &fakeCache{store: map[string][]byte{"key1": []byte("value")}}
+	c := &Cache{Name: "test", Config: &config.CachingConfig{}, L1: l1, L2: l2}
+
+	data, _, err := c.Retrieve("key1", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "value" {
+		t.Errorf("expected value, got %q", data)
+	}
+	if !l1.has("key1") {
+		t.Error("expected L2 hit to promote the object into L1")
+	}
+}
+
+func TestStoreWritesThroughSynchronously(t *testing.T) {
+	l1 := &fakeCache{}
+	l2 := &fakeCache{}
+	c := &Cache{Name: "test", Config: &config.CachingConfig{TieredWriteBack: false}, L1: l1, L2: l2}
+
+	if err := c.Store("key1", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !l1.has("key1") || !l2.has("key1") {
+		t.Error("expected Store to write through to both L1 and L2 before returning")
+	}
+}
+
+func TestStoreWriteBackIsAsync(t *testing.T) {
+	l1 := &fakeCache{}
+	l2 := &fakeCache{}
+	c := &Cache{Name: "test", Config: &config.CachingConfig{TieredWriteBack: true}, L1: l1, L2: l2}
+
+	if err := c.Store("key1", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !l1.has("key1") {
+		t.Error("expected Store to write through to L1 synchronously")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !l2.has("key1") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !l2.has("key1") {
+		t.Error("expected the async write-back to eventually reach L2")
+	}
+}
+
+func TestOnL1EvictSpillsSmallRawObjects(t *testing.T) {
+	l1 := &fakeCache{}
+	l2 := &fakeCache{}
+	c := &Cache{Name: "test", Config: &config.CachingConfig{}, L1: l1, L2: l2}
+
+	if err := c.Connect(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.onL1Evict("small", []byte("value"), nil)
+	if !l2.has("small") {
+		t.Error("expected a small, non-reference eviction to spill down to L2")
+	}
+
+	c.onL1Evict("oversized", make([]byte, maxSpillableBytes+1), nil)
+	if l2.has("oversized") {
+		t.Error("expected an eviction larger than maxSpillableBytes not to be spilled")
+	}
+}
+
+// fakeSerializableReference is a small ReferenceObject that can serialize
+// itself, exercising the reference-spill path of onL1Evict
+type fakeSerializableReference struct {
+	size int
+	data []byte
+}
+
+func (r *fakeSerializableReference) Size() int              { return r.size }
+func (r *fakeSerializableReference) Bytes() ([]byte, error) { return r.data, nil }
+
+// fakeUnserializableReference only reports a size, like a real ReferenceObject
+// that has no generic way to turn itself into bytes
+type fakeUnserializableReference struct {
+	size int
+}
+
+func (r *fakeUnserializableReference) Size() int { return r.size }
+
+func TestOnL1EvictSpillsSmallSerializableReferences(t *testing.T) {
+	l1 := &fakeCache{}
+	l2 := &fakeCache{}
+	c := &Cache{Name: "test", Config: &config.CachingConfig{}, L1: l1, L2: l2}
+
+	if err := c.Connect(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c.onL1Evict("small-ref", nil, &fakeSerializableReference{size: 5, data: []byte("value")})
+	if !l2.has("small-ref") {
+		t.Error("expected a small, serializable reference eviction to spill down to L2")
+	}
+
+	c.onL1Evict("oversized-ref", nil, &fakeSerializableReference{size: maxSpillableBytes + 1, data: []byte("value")})
+	if l2.has("oversized-ref") {
+		t.Error("expected a reference whose Size() exceeds maxSpillableBytes not to be spilled")
+	}
+
+	c.onL1Evict("unserializable-ref", nil, &fakeUnserializableReference{size: 5})
+	if l2.has("unserializable-ref") {
+		t.Error("expected a small but non-serializable reference not to be spilled")
+	}
+}
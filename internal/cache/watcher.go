@@ -0,0 +1,97 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package cache
+
+import (
+	"sync"
+
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// Watcher implements the Watch/Abandon half of the Cache interface: a
+// per-key subscription list guarded by a mutex, so that Cache implementations
+// can embed it to get request-coalescing without reimplementing the
+// subscriber bookkeeping themselves
+type Watcher struct {
+	mtx      sync.Mutex
+	watchers map[string][]chan WatchResult
+}
+
+// Watch registers the caller as a subscriber for cacheKey. The first caller
+// for a given key is reported as the owner (isOwner true); it should perform
+// the fill itself and deliver the result via Broadcast or Abandon. Any other
+// concurrent caller for the same key joins the existing subscriber list and
+// simply reads the result off ch
+func (w *Watcher) Watch(cacheKey string) (ch <-chan WatchResult, isOwner bool, cancel CancelFunc, err error) {
+	c := make(chan WatchResult, 1)
+
+	w.mtx.Lock()
+	if w.watchers == nil {
+		w.watchers = make(map[string][]chan WatchResult)
+	}
+	subs, exists := w.watchers[cacheKey]
+	w.watchers[cacheKey] = append(subs, c)
+	w.mtx.Unlock()
+
+	cancelFunc := func() {
+		w.mtx.Lock()
+		defer w.mtx.Unlock()
+		subs := w.watchers[cacheKey]
+		for i, s := range subs {
+			if s == c {
+				w.watchers[cacheKey] = append(subs[:i], subs[i+1:]...)
+				close(c)
+				break
+			}
+		}
+	}
+
+	return c, !exists, cancelFunc, nil
+}
+
+// Abandon notifies any subscribers registered via Watch for cacheKey that the
+// in-flight fill failed, delivering err to each of them via WatchResult.Err
+// before closing their channels, and logging the failure since it would
+// otherwise be invisible to anything but the owner that hit it
+func (w *Watcher) Abandon(cacheKey string, err error) {
+	w.mtx.Lock()
+	subs := w.watchers[cacheKey]
+	delete(w.watchers, cacheKey)
+	w.mtx.Unlock()
+
+	if err != nil && len(subs) > 0 {
+		log.Error("abandoning in-flight cache fill", log.Pairs{"cacheKey": cacheKey, "subscribers": len(subs), "detail": err.Error()})
+	}
+
+	for _, c := range subs {
+		c <- WatchResult{Err: err}
+		close(c)
+	}
+}
+
+// Broadcast delivers data to, and closes, every channel subscribed to
+// cacheKey via Watch, then clears the subscriber list for that key. Cache
+// implementations should call this once a fill they own completes
+// successfully, typically from within Store
+func (w *Watcher) Broadcast(cacheKey string, data []byte) {
+	w.mtx.Lock()
+	subs := w.watchers[cacheKey]
+	delete(w.watchers, cacheKey)
+	w.mtx.Unlock()
+
+	for _, c := range subs {
+		c <- WatchResult{Data: data}
+		close(c)
+	}
+}
@@ -0,0 +1,99 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package cache
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWatchFirstCallerIsOwner(t *testing.T) {
+	w := &Watcher{}
+
+	_, isOwner, _, err := w.Watch("key1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !isOwner {
+		t.Error("expected the first caller to be reported as the owner")
+	}
+
+	_, isOwner, _, err = w.Watch("key1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if isOwner {
+		t.Error("expected a second concurrent caller for the same key not to be the owner")
+	}
+}
+
+func TestBroadcastDeliversDataToAllSubscribers(t *testing.T) {
+	w := &Watcher{}
+
+	ch1, _, _, _ := w.Watch("key1")
+	ch2, _, _, _ := w.Watch("key1")
+
+	w.Broadcast("key1", []byte("value"))
+
+	for _, ch := range []<-chan WatchResult{ch1, ch2} {
+		res, ok := <-ch
+		if !ok {
+			t.Fatal("expected a result before the channel closed")
+		}
+		if string(res.Data) != "value" || res.Err != nil {
+			t.Errorf("unexpected result: %+v", res)
+		}
+		if _, stillOpen := <-ch; stillOpen {
+			t.Error("expected channel to be closed after delivery")
+		}
+	}
+}
+
+func TestAbandonDeliversErrorToSubscribers(t *testing.T) {
+	w := &Watcher{}
+
+	ch, _, _, _ := w.Watch("key1")
+	wantErr := errors.New("origin fetch failed")
+
+	w.Abandon("key1", wantErr)
+
+	res, ok := <-ch
+	if !ok {
+		t.Fatal("expected a result before the channel closed")
+	}
+	if res.Err != wantErr {
+		t.Errorf("expected subscriber to receive the abandon error, got %v", res.Err)
+	}
+	if res.Data != nil {
+		t.Errorf("expected no data on an abandoned fill, got %v", res.Data)
+	}
+}
+
+func TestCancelRemovesOnlyItsOwnSubscription(t *testing.T) {
+	w := &Watcher{}
+
+	ch1, _, cancel1, _ := w.Watch("key1")
+	ch2, _, _, _ := w.Watch("key1")
+
+	cancel1()
+
+	if _, stillOpen := <-ch1; stillOpen {
+		t.Error("expected cancelled subscriber's channel to be closed")
+	}
+
+	w.Broadcast("key1", []byte("value"))
+	if res, ok := <-ch2; !ok || string(res.Data) != "value" {
+		t.Error("expected remaining subscriber to still receive the broadcast")
+	}
+}
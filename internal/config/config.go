@@ -0,0 +1,73 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package config defines the Trickster configuration schema
+package config
+
+// Caches holds the configured, named cache backends, keyed by name; it is
+// populated from the loaded config file and consumed by
+// routing/registration.RegisterCaches to build the running cache registry
+var Caches = make(map[string]*CachingConfig)
+
+// CachingConfig defines the configuration for a single named cache backend
+type CachingConfig struct {
+	// Type identifies which Cache implementation to instantiate for this
+	// entry: "memory" (default), "memcache", or "tiered"
+	Type string `toml:"type"`
+
+	// ReapIntervalSecs sets how often the memory cache scans for and evicts
+	// expired objects; unused by other cache types
+	ReapIntervalSecs int `toml:"reap_interval_secs"`
+
+	// Memcache holds the connection settings used when Type is "memcache"
+	Memcache MemcacheConfig `toml:"memcache"`
+
+	// Tiers names exactly two other entries in Caches, in [L1, L2] order, to
+	// compose into a TieredCache when Type is "tiered"
+	Tiers []string `toml:"tiers"`
+
+	// TieredWriteBack, when Type is "tiered", writes through to L2 on a
+	// background goroutine instead of blocking Store on the L2 write
+	TieredWriteBack bool `toml:"tiered_write_back"`
+}
+
+// Origins holds the configured, named proxyable origins, keyed by name; it is
+// populated from the loaded config file and consumed by
+// routing/registration.RegisterProxyRoutes to build the running proxy clients
+var Origins = make(map[string]*OriginConfig)
+
+// OriginConfig defines the configuration for a single proxied origin
+type OriginConfig struct {
+	// Type identifies which proxy.Client implementation to instantiate for
+	// this origin: "prometheus" (default) or "influxdb"
+	Type string `toml:"type"`
+
+	// Host is the upstream address this origin's requests are proxied to
+	Host string `toml:"host"`
+
+	// CacheName names the entry in Caches this origin's responses are stored
+	// in and coalesced through
+	CacheName string `toml:"cache_name"`
+}
+
+// MemcacheConfig defines the connection settings for a Memcached-backed cache
+type MemcacheConfig struct {
+	// Servers is the list of memcached server addresses to connect to
+	Servers []string `toml:"servers"`
+
+	// Timeout is the client socket timeout, in milliseconds
+	Timeout int `toml:"timeout_ms"`
+
+	// MaxIdleConns caps the number of idle connections kept open per server
+	MaxIdleConns int `toml:"max_idle_conns"`
+}
@@ -0,0 +1,70 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package influxdb implements a proxy.Client for InfluxDB origins
+package influxdb
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/config"
+	"github.com/Comcast/trickster/internal/util/log"
+)
+
+// defaultTTL is how long a proxied query response is cached before a request
+// for the same query must be re-fetched from the origin
+const defaultTTL = time.Minute
+
+// Client proxies requests to an InfluxDB origin
+type Client struct {
+	Name   string
+	Config *config.OriginConfig
+	Cache  cache.Cache
+}
+
+// RegisterRoutes registers this origin's query path handler
+func (c *Client) RegisterRoutes(originName string, o *config.OriginConfig) {
+	http.HandleFunc("/"+originName+"/query", c.handleQuery)
+}
+
+// handleQuery serves an InfluxDB query request. It calls cache.Fetch rather
+// than fetching from the origin directly, so that concurrent requests for the
+// same query coalesce onto a single upstream fetch instead of each one
+// stampeding the origin
+func (c *Client) handleQuery(w http.ResponseWriter, r *http.Request) {
+	cacheKey := c.Name + "." + r.URL.RawQuery
+
+	body, err := cache.Fetch(c.Cache, c.Name, cacheKey, defaultTTL, func() ([]byte, error) {
+		return c.fetchUpstream(r)
+	})
+	if err != nil {
+		log.Error("error proxying influxdb query", log.Pairs{"originName": c.Name, "detail": err.Error()})
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Write(body)
+}
+
+// fetchUpstream issues the actual HTTP request to the InfluxDB origin
+func (c *Client) fetchUpstream(r *http.Request) ([]byte, error) {
+	resp, err := http.Get(c.Config.Host + r.URL.Path + "?" + r.URL.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
@@ -0,0 +1,35 @@
+/**
+* Copyright 2018 Comcast Cable Communications Management, LLC
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+* http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+// Package proxy defines the interface and shared registry that
+// origin-specific proxy clients (prometheus, influxdb, ...) implement and
+// register against
+package proxy
+
+import "github.com/Comcast/trickster/internal/config"
+
+// Supported values for OriginConfig.Type
+const (
+	OtPrometheus = "prometheus"
+	OtInfluxDb   = "influxdb"
+)
+
+// Client is implemented by each origin-type-specific proxy client and
+// registers that origin's request path handlers
+type Client interface {
+	RegisterRoutes(originName string, o *config.OriginConfig)
+}
+
+// Clients holds the instantiated, running proxy client for each configured
+// origin, keyed by origin name
+var Clients = make(map[string]Client)
@@ -17,6 +17,9 @@ import (
 	"strings"
 
 	"github.com/Comcast/trickster/internal/cache"
+	"github.com/Comcast/trickster/internal/cache/memcache"
+	"github.com/Comcast/trickster/internal/cache/memory"
+	"github.com/Comcast/trickster/internal/cache/tiered"
 	"github.com/Comcast/trickster/internal/config"
 	"github.com/Comcast/trickster/internal/proxy"
 	"github.com/Comcast/trickster/internal/proxy/influxdb"
@@ -24,11 +27,69 @@ import (
 	"github.com/Comcast/trickster/internal/util/log"
 )
 
-// RegisterCaches ... 
+// RegisterCaches iterates the caches configured in config.Caches, instantiates
+// the appropriate Cache implementation for each one's Type, connects it, and
+// adds it to the cache registry under its configured name. A cache that fails
+// to connect is logged and skipped rather than aborting the whole process, so
+// a single misconfigured cache only takes down the origins that reference it.
+// Tiered caches are registered in a second pass, since their L1/L2 tiers must
+// already be registered by name before they can be composed.
 func RegisterCaches() {
+	for k, cc := range config.Caches {
+		if strings.ToLower(cc.Type) == "tiered" {
+			continue
+		}
 
-}
+		var c cache.Cache
+
+		switch strings.ToLower(cc.Type) {
+		case "memcache":
+			log.Info("connecting to memcache cache", log.Pairs{"cacheName": k})
+			c = &memcache.Cache{Name: k, Config: cc}
+		default:
+			log.Info("connecting to memory cache", log.Pairs{"cacheName": k})
+			c = &memory.Cache{Name: k, Config: cc}
+		}
+
+		if err := c.Connect(); err != nil {
+			log.Error("unable to connect to cache", log.Pairs{"cacheName": k, "cacheType": cc.Type, "detail": err.Error()})
+			continue
+		}
+
+		cache.RegisterCache(k, c)
+	}
 
+	for k, cc := range config.Caches {
+		if strings.ToLower(cc.Type) != "tiered" {
+			continue
+		}
+
+		if len(cc.Tiers) != 2 {
+			log.Error("tiered cache must name exactly two tiers", log.Pairs{"cacheName": k, "tiers": cc.Tiers})
+			continue
+		}
+
+		l1, err := cache.GetCache(cc.Tiers[0])
+		if err != nil {
+			log.Error("unable to resolve L1 for tiered cache", log.Pairs{"cacheName": k, "tierName": cc.Tiers[0], "detail": err.Error()})
+			continue
+		}
+		l2, err := cache.GetCache(cc.Tiers[1])
+		if err != nil {
+			log.Error("unable to resolve L2 for tiered cache", log.Pairs{"cacheName": k, "tierName": cc.Tiers[1], "detail": err.Error()})
+			continue
+		}
+
+		log.Info("connecting to tiered cache", log.Pairs{"cacheName": k, "l1": cc.Tiers[0], "l2": cc.Tiers[1]})
+		c := &tiered.Cache{Name: k, Config: cc, L1: l1, L2: l2}
+		if err := c.Connect(); err != nil {
+			log.Error("unable to connect to cache", log.Pairs{"cacheName": k, "cacheType": cc.Type, "detail": err.Error()})
+			continue
+		}
+
+		cache.RegisterCache(k, c)
+	}
+}
 
 // RegisterProxyRoutes ...
 func RegisterProxyRoutes() {
@@ -42,7 +103,8 @@ func RegisterProxyRoutes() {
 
 		c, err = cache.GetCache(o.CacheName)
 		if err != nil {
-			log.Fatal(1, "invalid cache name in origin config", log.Pairs{"originName": k, "cacheName": o.CacheName})
+			log.Error("invalid cache name in origin config, skipping origin", log.Pairs{"originName": k, "cacheName": o.CacheName})
+			continue
 		}
 		switch strings.ToLower(o.Type) {
 		case proxy.OtPrometheus, "":
@@ -57,4 +119,4 @@ func RegisterProxyRoutes() {
 		proxy.Clients[k] = client
 
 	}
-}
\ No newline at end of file
+}